@@ -0,0 +1,17 @@
+// External invalidation for event-driven cache coherency: lets callers wire
+// the cache up to an out-of-band invalidation bus (Postgres LISTEN/NOTIFY,
+// Redis pub/sub, a Kafka consumer, ...).
+
+package lru
+
+// WithInvalidationSource spawns a goroutine that drains ch for the lifetime
+// of the cache, deleting each key it receives (invoking RemovalFunc). The
+// goroutine exits when ch is closed.
+//
+// ch must be a <-chan Key: this option only wires up on a Typed[Key, Value]
+// cache (i.e. LRU, which is built on exactly that instantiation). Passing it
+// to NewTyped with any other K makes NewTyped panic rather than silently
+// doing nothing.
+func WithInvalidationSource(ch <-chan Key) Option {
+	return func(c *config) { c.invalidationSource = ch }
+}
@@ -0,0 +1,106 @@
+package lru
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestInvalidateKeys(t *testing.T) {
+	var removedKeys []string
+	removal := func(k Key, v Value) {
+		removedKeys = append(removedKeys, k.(string))
+	}
+	c := New(nil, removal, 4, 0)
+	c.Set("key1", 1)
+	c.Set("key2", 2)
+	c.Set("key3", 3)
+
+	c.InvalidateKeys("key1", "key3", "missing")
+	if len(removedKeys) != 2 {
+		t.Fatalf("removedKeys = %v, want 2 entries", removedKeys)
+	}
+	if _, ok := c.Get("key2"); !ok {
+		t.Errorf("key2 should still be cached")
+	}
+}
+
+func TestInvalidatePrefix(t *testing.T) {
+	var removedKeys []string
+	removal := func(k Key, v Value) {
+		removedKeys = append(removedKeys, k.(string))
+	}
+	c := New(nil, removal, 4, 0)
+	c.Set("user:1", 1)
+	c.Set("user:2", 2)
+	c.Set("org:1", 3)
+
+	c.InvalidatePrefix(func(k Key) bool {
+		return strings.HasPrefix(k.(string), "user:")
+	})
+	if len(removedKeys) != 2 {
+		t.Fatalf("removedKeys = %v, want 2 entries", removedKeys)
+	}
+	if _, ok := c.Get("org:1"); !ok {
+		t.Errorf("org:1 should still be cached")
+	}
+}
+
+func TestWithInvalidationSource(t *testing.T) {
+	var removed atomic.Int64
+	removal := func(k Key, v Value) {
+		removed.Add(1)
+	}
+	ch := make(chan Key)
+	c := New(nil, removal, 4, 0, WithInvalidationSource(ch))
+	c.Set("key", 1)
+
+	ch <- "key"
+	deadline := time.Now().Add(time.Second)
+	for removed.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := removed.Load(); got != 1 {
+		t.Errorf("removed = %d, want 1", got)
+	}
+	if _, ok := c.Get("key"); ok {
+		t.Errorf("key should have been invalidated")
+	}
+	close(ch)
+}
+
+func TestNewTypedWithInvalidationSource(t *testing.T) {
+	// NewTyped[Key, Value] is exactly what New is built on, so the option
+	// must work there too, not just through the LRU wrapper.
+	var removed atomic.Int64
+	removal := func(k Key, v Value) {
+		removed.Add(1)
+	}
+	ch := make(chan Key)
+	c := NewTyped[Key, Value](nil, removal, 4, 0, WithInvalidationSource(ch))
+	c.Set("key", 1)
+
+	ch <- "key"
+	deadline := time.Now().Add(time.Second)
+	for removed.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := removed.Load(); got != 1 {
+		t.Errorf("removed = %d, want 1", got)
+	}
+	close(ch)
+}
+
+func TestNewTypedWithInvalidationSourcePanicsOnMismatchedKeyType(t *testing.T) {
+	// WithInvalidationSource's channel is <-chan Key; using it on a Typed
+	// cache with any other K must not silently compile into a no-op.
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected NewTyped to panic when K != Key")
+		}
+	}()
+	ch := make(chan Key)
+	defer close(ch)
+	NewTyped[string, int](nil, nil, 4, 0, WithInvalidationSource(ch))
+}
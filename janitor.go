@@ -0,0 +1,13 @@
+// Background janitor goroutine that proactively sweeps expired entries,
+// instead of relying on a key being accessed again or FlushExpired being
+// called manually.
+
+package lru
+
+import "time"
+
+// WithJanitor spawns a background goroutine that calls FlushExpired on the
+// given interval, for the lifetime of the cache or until Close is called.
+func WithJanitor(interval time.Duration) Option {
+	return func(c *config) { c.janitorInterval = interval }
+}
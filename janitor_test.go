@@ -0,0 +1,37 @@
+package lru
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestJanitor(t *testing.T) {
+	var removed atomic.Int64
+	removal := func(k Key, v Value) {
+		removed.Add(1)
+	}
+	c := New(nil, removal, 4, 10*time.Millisecond, WithJanitor(5*time.Millisecond))
+	defer c.Close()
+
+	c.Set("key", 1)
+	if _, ok := c.NextExpiration(); !ok {
+		t.Errorf("expected a pending expiration")
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if got := removed.Load(); got != 1 {
+		t.Errorf("removed = %d, want 1 (janitor should have flushed it)", got)
+	}
+	if c.Len() != 0 {
+		t.Errorf("Len() = %d, want 0", c.Len())
+	}
+}
+
+func TestNextExpirationNoTTL(t *testing.T) {
+	c := New(nil, nil, 4, 0)
+	c.Set("key", 1)
+	if _, ok := c.NextExpiration(); ok {
+		t.Errorf("expected no pending expiration without a TTL")
+	}
+}
@@ -24,8 +24,6 @@
 package lru
 
 import (
-	"container/list"
-	"sync"
 	"time"
 )
 
@@ -34,170 +32,96 @@ type Value interface{}
 type AddFunc func(Key) Value
 type RemovalFunc func(Key, Value)
 
-// container for user data
-type entry struct {
-	Key        Key
-	Value      Value
-	lastUpdate time.Time
-}
-
-// Cache for function Func.
+// Cache for function Func. LRU is a thin wrapper around Typed[Key, Value]
+// kept for callers who don't know their key/value types at compile time; see
+// Typed for a generic, cast-free alternative.
 type LRU struct {
-	mu          sync.Mutex
-	addFunc     AddFunc
-	removalFunc RemovalFunc
-	list        *list.List
-	table       map[Key]*list.Element
-	// how many entries we are lmiting to
-	capacity int
-	ttl      time.Duration // how long a value is considered good for (0 to disable)
+	t *Typed[Key, Value]
 }
 
 // Create a new LRU cache with the desired capacity and optional functions to fetch new items, or
-// notify on removal. If a TTL is set, entries will only be considered valid for the TTL duration
-func New(a AddFunc, r RemovalFunc, capacity int, ttl time.Duration) *LRU {
-	if capacity < 1 {
-		panic("capacity < 1")
-	}
-
-	return &LRU{
-		addFunc:     a,
-		removalFunc: r,
-		list:        list.New(),
-		table:       make(map[Key]*list.Element),
-		capacity:    capacity,
-		ttl:         ttl,
-	}
+// notify on removal. If a TTL is set, entries will only be considered valid for the TTL duration.
+// By default entries are evicted least-recently-used first; pass WithPolicy to use a different
+// eviction Policy (e.g. SievePolicy).
+func New(a AddFunc, r RemovalFunc, capacity int, ttl time.Duration, opts ...Option) *LRU {
+	return &LRU{t: NewTyped[Key, Value](TypedAddFunc[Key, Value](a), TypedRemovalFunc[Key, Value](r), capacity, ttl, opts...)}
+}
+
+// DisableTouchOnUpdate changes weather the timestamp used to compare TTL is updated when an element is updated
+func (lru *LRU) DisableTouchOnUpdate() {
+	lru.t.DisableTouchOnUpdate()
 }
 
 // Fetch value for key in the cache, calling AddFunc to compute it if necessary.
 // This updates the values position in the LRU cache
 func (lru *LRU) Get(key Key) (v Value, ok bool) {
-	lru.mu.Lock()
-	defer lru.mu.Unlock()
-
-	element := lru.table[key]
-	if element == nil {
-		if lru.addFunc != nil {
-			v := lru.addFunc(key)
-			lru.addNew(key, v)
-			return v, true
-		}
-		return nil, false
-	}
-	e := element.Value.(*entry)
-	if lru.ttl > 0 {
-		delta := time.Now().Sub(e.lastUpdate)
-		if delta > lru.ttl {
-			if lru.removalFunc != nil {
-				lru.removalFunc(e.Key, e.Value)
-			}
-			lru.list.Remove(element)
-			delete(lru.table, key)
-
-			// now we also need to conditionally fill this
-			if lru.addFunc != nil {
-				v := lru.addFunc(key)
-				lru.addNew(key, v)
-				return v, true
-			}
-			return nil, false
-		}
-	}
-	lru.list.MoveToFront(element)
-	return element.Value.(*entry).Value, true
+	return lru.t.Get(key)
 }
 
 // Set a new entry in the LRU cache
 func (lru *LRU) Set(key Key, value Value) {
-	lru.mu.Lock()
-	defer lru.mu.Unlock()
-
-	if element := lru.table[key]; element != nil {
-		lru.updateInplace(element, value)
-	} else {
-		lru.addNew(key, value)
-	}
+	lru.t.Set(key, value)
 }
 
 func (lru *LRU) Delete(key Key) bool {
-	lru.mu.Lock()
-	defer lru.mu.Unlock()
-
-	element := lru.table[key]
-	if element == nil {
-		return false
-	}
+	return lru.t.Delete(key)
+}
 
-	if lru.removalFunc != nil {
-		n := element.Value.(*entry)
-		lru.removalFunc(n.Key, n.Value)
-	}
+// InvalidateKeys deletes keys from the cache, calling RemovalFunc for each
+// one present.
+func (lru *LRU) InvalidateKeys(keys ...Key) {
+	lru.t.InvalidateKeys(keys...)
+}
 
-	lru.list.Remove(element)
-	delete(lru.table, key)
-	return true
+// InvalidatePrefix deletes every entry whose key matches the given
+// predicate, calling RemovalFunc for each one removed.
+func (lru *LRU) InvalidatePrefix(match func(Key) bool) {
+	lru.t.InvalidatePrefix(match)
 }
 
 // Number of items currently in the LRU cache.
 func (lru *LRU) Len() int {
-	return lru.list.Len()
+	return lru.t.Len()
 }
 
 func (lru *LRU) Capacity() int {
-	return lru.capacity
+	return lru.t.Capacity()
 }
 
 // Iterate over the cache in LRU order. Useful for debugging.
 func (lru *LRU) Iter(keys chan Key, values chan Value) {
-	for e := lru.list.Front(); e != nil; e = e.Next() {
-		keys <- e.Value.(*entry).Key
-		values <- e.Value.(*entry).Value
-	}
-	close(keys)
-	close(values)
+	lru.t.Iter(keys, values)
 }
 
 // Flush all entries calling RemovalFunc as needed
 func (lru *LRU) Flush() {
-	if lru.removalFunc != nil {
-		for e := lru.list.Front(); e != nil; e = e.Next() {
-			n := e.Value.(*entry)
-			lru.removalFunc(n.Key, n.Value)
-		}
-	}
-	lru.list.Init()
-	lru.table = make(map[Key]*list.Element)
-}
-
-func (lru *LRU) updateInplace(element *list.Element, value Value) {
-	e := element.Value.(*entry)
-	e.Value = value
-	if lru.ttl > 0 {
-		e.lastUpdate = time.Now()
-	}
-	lru.list.MoveToFront(element)
-}
-
-func (lru *LRU) addNew(key Key, value Value) {
-	e := &entry{Key: key, Value: value}
-	if lru.ttl > 0 {
-		e.lastUpdate = time.Now()
-	}
-	element := lru.list.PushFront(e)
-	lru.table[key] = element
-	lru.checkCapacity()
-}
-
-func (lru *LRU) checkCapacity() {
-	// Partially duplicated from Delete
-	for lru.list.Len() > lru.capacity {
-		delElem := lru.list.Back()
-		delValue := delElem.Value.(*entry)
-		lru.list.Remove(delElem)
-		delete(lru.table, delValue.Key)
-		if lru.removalFunc != nil {
-			lru.removalFunc(delValue.Key, delValue.Value)
-		}
-	}
+	lru.t.Flush()
+}
+
+// FlushN flushes up to n of the least recently used entries, calling RemovalFunc as needed
+func (lru *LRU) FlushN(n int) {
+	lru.t.FlushN(n)
+}
+
+// FlushExpired flushes entries that are expired based on the configured TTL
+func (lru *LRU) FlushExpired() {
+	lru.t.FlushExpired()
+}
+
+// Stats returns a snapshot of the cache's built-in counters.
+func (lru *LRU) Stats() Stats {
+	return lru.t.Stats()
+}
+
+// Close stops the background janitor goroutine started by WithJanitor, if
+// any. It is safe to call more than once, and safe to call on a cache that
+// was never given WithJanitor.
+func (lru *LRU) Close() {
+	lru.t.Close()
+}
+
+// NextExpiration reports the time at which the next entry will expire, so
+// that advanced users can drive their own timer instead of WithJanitor.
+func (lru *LRU) NextExpiration() (time.Time, bool) {
+	return lru.t.NextExpiration()
 }
@@ -27,6 +27,14 @@ import (
 	"time"
 )
 
+// Integer is the set of integer types usable as a TypedCounter's value,
+// defined locally so this package stays dependency-free rather than
+// importing golang.org/x/exp/constraints.
+type Integer interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr
+}
+
 // a LRU counter that calls a function when an item is removed
 type LRUCounter struct {
 	lru *LRU
@@ -40,7 +48,7 @@ func NewLRUCounterTTL(removalFunc func(interface{}, int64), capacity int, ttl ti
 		removalFunc(key, vv)
 	}
 	l := New(nil, r, capacity, ttl)
-	return &LRUCounter{l}
+	return &LRUCounter{lru: l}
 }
 
 // Create a new LRU cache for removalFunc with the desired capacity.
@@ -90,3 +98,65 @@ func (c *LRUCounter) Incr(key interface{}, value int64) {
 	c.lru.Set(key, value)
 	c.Unlock()
 }
+
+// TypedCounter and the rest of this section are new additions to the
+// package, not part of the file's original Lars Buitinck-derived LRUCounter
+// above, and make no claim under that header's copyright.
+//
+// TypedCounter is the generic counterpart to LRUCounter: an LRU cache for
+// counting, constrained to integer value types at compile time instead of
+// asserting on interface{}.
+type TypedCounter[K comparable, N Integer] struct {
+	t *Typed[K, N]
+	sync.Mutex
+}
+
+// NewTypedCounterTTL creates a new TypedCounter for removalFunc with the desired capacity and ttl.
+func NewTypedCounterTTL[K comparable, N Integer](removalFunc func(K, N), capacity int, ttl time.Duration) *TypedCounter[K, N] {
+	t := NewTyped[K, N](nil, removalFunc, capacity, ttl)
+	return &TypedCounter[K, N]{t: t}
+}
+
+// NewTypedCounter creates a new TypedCounter for removalFunc with the desired capacity.
+func NewTypedCounter[K comparable, N Integer](removalFunc func(K, N), capacity int) *TypedCounter[K, N] {
+	return NewTypedCounterTTL[K, N](removalFunc, capacity, 0)
+}
+
+// DisableTouchOnUpdate changes weather the timestamp used to compare TTL is updated when an element is updated
+func (c *TypedCounter[K, N]) DisableTouchOnUpdate() {
+	c.t.DisableTouchOnUpdate()
+}
+
+// Fetch value for key in the cache, updating it's LRU position
+func (c *TypedCounter[K, N]) Get(key K) (value N, ok bool) {
+	return c.t.Get(key)
+}
+
+// Number of items currently in the cache.
+func (c *TypedCounter[K, N]) Len() int {
+	return c.t.Len()
+}
+
+func (c *TypedCounter[K, N]) Capacity() int {
+	return c.t.Capacity()
+}
+
+// Flush all entries
+func (c *TypedCounter[K, N]) Flush() {
+	c.t.Flush()
+}
+
+// FlushExpired flushes entries that are expired based on the configured TTL
+func (c *TypedCounter[K, N]) FlushExpired() {
+	c.t.FlushExpired()
+}
+
+// Incr the key by value (goroutine safe)
+func (c *TypedCounter[K, N]) Incr(key K, value N) {
+	c.Lock()
+	if vv, ok := c.t.Get(key); ok {
+		value += vv
+	}
+	c.t.Set(key, value)
+	c.Unlock()
+}
@@ -34,3 +34,34 @@ func TestLRUCounter(t *testing.T) {
 		t.Errorf("total = %d", total)
 	}
 }
+
+func TestTypedCounter(t *testing.T) {
+	var removed int64
+	var total int64
+	var removedKeys []string
+
+	removalFunc := func(k string, v int64) {
+		removed += 1
+		total += v
+		removedKeys = append(removedKeys, k)
+	}
+	c := NewTypedCounter[string, int64](removalFunc, 4)
+	c.Incr("key1", 1)
+	c.Incr("key2", 1)
+	c.Incr("key2", 1)
+	c.Incr("key3", 1)
+	c.Incr("key3", 1)
+	c.Incr("key4", 1)
+	c.Incr("key5", 1)
+
+	if removed != 1 {
+		t.Errorf("removed = %d", removed)
+	}
+	c.Flush()
+	if removed != 5 {
+		t.Errorf("removed = %d", removed)
+	}
+	if total != 7 {
+		t.Errorf("total = %d", total)
+	}
+}
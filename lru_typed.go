@@ -0,0 +1,451 @@
+// Generic LRU Cache for typed data with least-recently used (LRU) eviction
+// strategy. This is the type-safe counterpart to LRU; see lru.go.
+
+package lru
+
+import (
+	"container/list"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TypedAddFunc computes the value for a key missing from the cache.
+type TypedAddFunc[K comparable, V any] func(K) V
+
+// TypedRemovalFunc is notified when a key/value pair leaves the cache,
+// whether through eviction, expiry, or an explicit Delete/Flush.
+type TypedRemovalFunc[K comparable, V any] func(K, V)
+
+// container for user data
+type entry[K comparable, V any] struct {
+	Key        K
+	Value      V
+	lastUpdate time.Time
+	visitedBit bool
+}
+
+func (e *entry[K, V]) visited() bool     { return e.visitedBit }
+func (e *entry[K, V]) setVisited(v bool) { e.visitedBit = v }
+
+// Typed is a type-safe LRU cache, parameterized on key and value types. It
+// has the same semantics as LRU, but without the interface{} casts.
+type Typed[K comparable, V any] struct {
+	mu            sync.Mutex
+	addFunc       TypedAddFunc[K, V]
+	removalFunc   TypedRemovalFunc[K, V]
+	list          *list.List
+	table         map[K]*list.Element
+	capacity      int
+	ttl           time.Duration // how long a value is considered good for (0 to disable)
+	touchOnUpdate bool
+	policy        Policy
+	sink          MetricsSink
+
+	hits         int64
+	misses       int64
+	evictions    int64
+	expirations  int64
+	addFuncCalls int64
+
+	closeOnce   sync.Once
+	janitorStop chan struct{}
+}
+
+// NewTyped creates a new Typed cache with the desired capacity and optional
+// functions to fetch new items, or notify on removal. If a TTL is set,
+// entries will only be considered valid for the TTL duration. By default
+// entries are evicted least-recently-used first; pass WithPolicy to use a
+// different eviction Policy (e.g. SievePolicy).
+func NewTyped[K comparable, V any](a TypedAddFunc[K, V], r TypedRemovalFunc[K, V], capacity int, ttl time.Duration, opts ...Option) *Typed[K, V] {
+	if capacity < 1 {
+		panic("capacity < 1")
+	}
+
+	cfg := parseConfig(opts)
+
+	policy := cfg.policy
+	if cp, ok := policy.(clonablePolicy); ok {
+		policy = cp.clone()
+	}
+
+	t := &Typed[K, V]{
+		addFunc:       a,
+		removalFunc:   r,
+		list:          list.New(),
+		table:         make(map[K]*list.Element),
+		capacity:      capacity,
+		ttl:           ttl,
+		touchOnUpdate: true,
+		policy:        policy,
+		sink:          cfg.sink,
+	}
+	if cfg.janitorInterval > 0 {
+		t.startJanitor(cfg.janitorInterval)
+		runtime.SetFinalizer(t, (*Typed[K, V]).Close)
+	}
+	if cfg.invalidationSource != nil {
+		ch, ok := any(cfg.invalidationSource).(<-chan K)
+		if !ok {
+			panic("lru: WithInvalidationSource requires a Typed[Key, Value] cache (K must be Key)")
+		}
+		t.startInvalidationSource(ch)
+	}
+	return t
+}
+
+// startInvalidationSource spawns the goroutine backing WithInvalidationSource.
+func (lru *Typed[K, V]) startInvalidationSource(ch <-chan K) {
+	go func() {
+		for key := range ch {
+			lru.Delete(key)
+		}
+	}()
+}
+
+// startJanitor spawns the background goroutine backing WithJanitor.
+func (lru *Typed[K, V]) startJanitor(interval time.Duration) {
+	lru.janitorStop = make(chan struct{})
+	ticker := time.NewTicker(interval)
+	stop := lru.janitorStop
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				lru.FlushExpired()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Close stops the background janitor goroutine started by WithJanitor, if
+// any. It is safe to call more than once, and safe to call on a cache that
+// was never given WithJanitor.
+func (lru *Typed[K, V]) Close() {
+	lru.closeOnce.Do(func() {
+		if lru.janitorStop != nil {
+			close(lru.janitorStop)
+		}
+		runtime.SetFinalizer(lru, nil)
+	})
+}
+
+// NextExpiration reports the time at which the next entry will expire, so
+// that advanced users can drive their own timer instead of WithJanitor. It
+// reports false if no TTL is configured or the cache is empty.
+func (lru *Typed[K, V]) NextExpiration() (time.Time, bool) {
+	lru.mu.Lock()
+	defer lru.mu.Unlock()
+
+	if lru.ttl <= 0 {
+		return time.Time{}, false
+	}
+	var earliest time.Time
+	found := false
+	for e := lru.list.Front(); e != nil; e = e.Next() {
+		n := e.Value.(*entry[K, V])
+		exp := n.lastUpdate.Add(lru.ttl)
+		if !found || exp.Before(earliest) {
+			earliest = exp
+			found = true
+		}
+	}
+	return earliest, found
+}
+
+// Stats returns a snapshot of the cache's built-in counters.
+func (lru *Typed[K, V]) Stats() Stats {
+	return Stats{
+		Hits:         atomic.LoadInt64(&lru.hits),
+		Misses:       atomic.LoadInt64(&lru.misses),
+		Evictions:    atomic.LoadInt64(&lru.evictions),
+		Expirations:  atomic.LoadInt64(&lru.expirations),
+		AddFuncCalls: atomic.LoadInt64(&lru.addFuncCalls),
+	}
+}
+
+func (lru *Typed[K, V]) incrHit() {
+	atomic.AddInt64(&lru.hits, 1)
+	if lru.sink != nil {
+		lru.sink.Inc("hit")
+	}
+}
+
+func (lru *Typed[K, V]) incrMiss() {
+	atomic.AddInt64(&lru.misses, 1)
+	if lru.sink != nil {
+		lru.sink.Inc("miss")
+	}
+}
+
+func (lru *Typed[K, V]) incrEviction() {
+	atomic.AddInt64(&lru.evictions, 1)
+	if lru.sink != nil {
+		lru.sink.Inc("eviction")
+	}
+}
+
+func (lru *Typed[K, V]) incrExpiration() {
+	atomic.AddInt64(&lru.expirations, 1)
+	if lru.sink != nil {
+		lru.sink.Inc("expiration")
+	}
+}
+
+func (lru *Typed[K, V]) incrAddFuncCall() {
+	atomic.AddInt64(&lru.addFuncCalls, 1)
+	if lru.sink != nil {
+		lru.sink.Inc("add_func_call")
+	}
+}
+
+// DisableTouchOnUpdate changes weather the timestamp used to compare TTL is updated when an element is updated
+func (lru *Typed[K, V]) DisableTouchOnUpdate() {
+	lru.mu.Lock()
+	defer lru.mu.Unlock()
+	lru.touchOnUpdate = false
+}
+
+// Get fetches the value for key in the cache, calling AddFunc to compute it if necessary.
+// This updates the values position in the LRU cache
+func (lru *Typed[K, V]) Get(key K) (v V, ok bool) {
+	lru.mu.Lock()
+	defer lru.mu.Unlock()
+
+	element := lru.table[key]
+	if element == nil {
+		lru.incrMiss()
+		if lru.addFunc != nil {
+			lru.incrAddFuncCall()
+			v := lru.addFunc(key)
+			lru.addNew(key, v)
+			return v, true
+		}
+		var zero V
+		return zero, false
+	}
+	e := element.Value.(*entry[K, V])
+	if lru.ttl > 0 {
+		delta := time.Now().Sub(e.lastUpdate)
+		if delta > lru.ttl {
+			lru.incrExpiration()
+			if lru.removalFunc != nil {
+				lru.removalFunc(e.Key, e.Value)
+			}
+			lru.policy.OnRemove(lru.list, element)
+			lru.list.Remove(element)
+			delete(lru.table, key)
+
+			// now we also need to conditionally fill this
+			lru.incrMiss()
+			if lru.addFunc != nil {
+				lru.incrAddFuncCall()
+				v := lru.addFunc(key)
+				lru.addNew(key, v)
+				return v, true
+			}
+			var zero V
+			return zero, false
+		}
+	}
+	lru.incrHit()
+	lru.policy.OnAccess(lru.list, element)
+	return element.Value.(*entry[K, V]).Value, true
+}
+
+// Set a new entry in the cache
+func (lru *Typed[K, V]) Set(key K, value V) {
+	lru.mu.Lock()
+	defer lru.mu.Unlock()
+
+	if element := lru.table[key]; element != nil {
+		lru.updateInplace(element, value)
+	} else {
+		lru.addNew(key, value)
+	}
+}
+
+// Delete removes key from the cache, calling RemovalFunc if set. It reports
+// whether the key was present.
+func (lru *Typed[K, V]) Delete(key K) bool {
+	lru.mu.Lock()
+	defer lru.mu.Unlock()
+
+	element := lru.table[key]
+	if element == nil {
+		return false
+	}
+
+	if lru.removalFunc != nil {
+		n := element.Value.(*entry[K, V])
+		lru.removalFunc(n.Key, n.Value)
+	}
+
+	lru.policy.OnRemove(lru.list, element)
+	lru.list.Remove(element)
+	delete(lru.table, key)
+	return true
+}
+
+// InvalidateKeys deletes keys from the cache, calling RemovalFunc for each
+// one present. It's meant to be driven by an out-of-band invalidation
+// source (see WithInvalidationSource); InvalidatePrefix covers bulk
+// invalidation by predicate instead of by exact key.
+func (lru *Typed[K, V]) InvalidateKeys(keys ...K) {
+	for _, key := range keys {
+		lru.Delete(key)
+	}
+}
+
+// InvalidatePrefix deletes every entry whose key matches the given
+// predicate, calling RemovalFunc for each one removed.
+func (lru *Typed[K, V]) InvalidatePrefix(match func(K) bool) {
+	lru.mu.Lock()
+	defer lru.mu.Unlock()
+
+	var toRemove []*list.Element
+	for e := lru.list.Front(); e != nil; e = e.Next() {
+		n := e.Value.(*entry[K, V])
+		if match(n.Key) {
+			toRemove = append(toRemove, e)
+		}
+	}
+	for _, e := range toRemove {
+		n := e.Value.(*entry[K, V])
+		lru.policy.OnRemove(lru.list, e)
+		lru.list.Remove(e)
+		delete(lru.table, n.Key)
+		if lru.removalFunc != nil {
+			lru.removalFunc(n.Key, n.Value)
+		}
+	}
+}
+
+// Len returns the number of items currently in the cache.
+func (lru *Typed[K, V]) Len() int {
+	lru.mu.Lock()
+	defer lru.mu.Unlock()
+
+	return lru.list.Len()
+}
+
+func (lru *Typed[K, V]) Capacity() int {
+	lru.mu.Lock()
+	defer lru.mu.Unlock()
+
+	return lru.capacity
+}
+
+// Iter iterates over the cache in LRU order. Useful for debugging.
+func (lru *Typed[K, V]) Iter(keys chan K, values chan V) {
+	lru.mu.Lock()
+	defer lru.mu.Unlock()
+
+	for e := lru.list.Front(); e != nil; e = e.Next() {
+		n := e.Value.(*entry[K, V])
+		keys <- n.Key
+		values <- n.Value
+	}
+	close(keys)
+	close(values)
+}
+
+// Flush removes all entries, calling RemovalFunc as needed
+func (lru *Typed[K, V]) Flush() {
+	lru.mu.Lock()
+	defer lru.mu.Unlock()
+
+	for e := lru.list.Front(); e != nil; e = e.Next() {
+		n := e.Value.(*entry[K, V])
+		lru.policy.OnRemove(lru.list, e)
+		if lru.removalFunc != nil {
+			lru.removalFunc(n.Key, n.Value)
+		}
+	}
+	lru.list.Init()
+	lru.table = make(map[K]*list.Element)
+}
+
+// FlushN removes up to n of the least recently used entries, calling
+// RemovalFunc as needed.
+func (lru *Typed[K, V]) FlushN(n int) {
+	lru.mu.Lock()
+	defer lru.mu.Unlock()
+
+	for i := 0; i < n && lru.list.Len() > 0; i++ {
+		delElem := lru.list.Back()
+		delValue := delElem.Value.(*entry[K, V])
+		lru.policy.OnRemove(lru.list, delElem)
+		lru.list.Remove(delElem)
+		delete(lru.table, delValue.Key)
+		if lru.removalFunc != nil {
+			lru.removalFunc(delValue.Key, delValue.Value)
+		}
+	}
+}
+
+// FlushExpired removes entries that are expired based on the configured TTL
+func (lru *Typed[K, V]) FlushExpired() {
+	lru.mu.Lock()
+	defer lru.mu.Unlock()
+
+	if lru.ttl <= 0 {
+		return
+	}
+	now := time.Now()
+	for e := lru.list.Front(); e != nil; {
+		next := e.Next()
+		n := e.Value.(*entry[K, V])
+		if now.Sub(n.lastUpdate) > lru.ttl {
+			lru.incrExpiration()
+			lru.policy.OnRemove(lru.list, e)
+			lru.list.Remove(e)
+			delete(lru.table, n.Key)
+			if lru.removalFunc != nil {
+				lru.removalFunc(n.Key, n.Value)
+			}
+		}
+		e = next
+	}
+}
+
+func (lru *Typed[K, V]) updateInplace(element *list.Element, value V) {
+	e := element.Value.(*entry[K, V])
+	e.Value = value
+	if lru.ttl > 0 && lru.touchOnUpdate {
+		e.lastUpdate = time.Now()
+	}
+	lru.policy.OnAccess(lru.list, element)
+}
+
+func (lru *Typed[K, V]) addNew(key K, value V) {
+	e := &entry[K, V]{Key: key, Value: value}
+	if lru.ttl > 0 {
+		e.lastUpdate = time.Now()
+	}
+	element := lru.list.PushFront(e)
+	lru.table[key] = element
+	lru.policy.OnInsert(lru.list, element)
+	lru.checkCapacity()
+}
+
+func (lru *Typed[K, V]) checkCapacity() {
+	// Partially duplicated from Delete
+	for lru.list.Len() > lru.capacity {
+		delElem := lru.policy.Evict(lru.list)
+		if delElem == nil {
+			return
+		}
+		delValue := delElem.Value.(*entry[K, V])
+		lru.list.Remove(delElem)
+		delete(lru.table, delValue.Key)
+		lru.incrEviction()
+		if lru.removalFunc != nil {
+			lru.removalFunc(delValue.Key, delValue.Value)
+		}
+	}
+}
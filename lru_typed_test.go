@@ -0,0 +1,65 @@
+package lru
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTyped(t *testing.T) {
+	var total int64
+	var removed int64
+	var removedKeys []string
+	newItem := func(k string) int64 {
+		total += 1
+		t.Logf("newItem %d", total)
+		return total
+	}
+	removal := func(k string, v int64) {
+		t.Logf("removal %v %v", k, v)
+		removed++
+		removedKeys = append(removedKeys, k)
+	}
+	c := NewTyped[string, int64](newItem, removal, 4, 0)
+	for _, k := range []string{"key1", "key2", "key3"} {
+		if v, ok := c.Get(k); ok && v != total {
+			t.Errorf("%s got %d expected %d", k, v, total)
+		}
+	}
+	for i, k := range []string{"key1", "key2", "key3"} {
+		if v, ok := c.Get(k); ok && v != int64(i+1) {
+			t.Errorf("%s got %d expected %d", k, v, i+1)
+		}
+	}
+
+	if removed != 0 {
+		t.Errorf("removed = %d", removed)
+	}
+	c.Flush()
+	if removed != 3 {
+		t.Errorf("removed = %d", removed)
+	}
+	if total != 3 {
+		t.Errorf("total = %d", total)
+	}
+}
+
+func TestTypedExpiry(t *testing.T) {
+	var removed int64
+	removal := func(k string, v int64) {
+		t.Logf("removal %v %v", k, v)
+		removed++
+	}
+	c := NewTyped[string, int64](nil, removal, 4, 10*time.Millisecond)
+	c.Set("key", 1)
+	_, ok := c.Get("key")
+	if !ok {
+		t.Errorf("entry should still be there")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if _, ok = c.Get("key"); ok {
+		t.Errorf("should be gone now")
+	}
+	if removed != 1 {
+		t.Errorf("unexpected removal")
+	}
+}
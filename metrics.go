@@ -0,0 +1,25 @@
+// Built-in cache metrics: hits, misses, evictions, expirations, and
+// AddFunc invocations, optionally mirrored to an external MetricsSink.
+
+package lru
+
+// Stats is a snapshot of a cache's built-in counters.
+type Stats struct {
+	Hits         int64
+	Misses       int64
+	Evictions    int64
+	Expirations  int64
+	AddFuncCalls int64
+}
+
+// MetricsSink receives a notification every time one of the counters behind
+// Stats is incremented, so callers can wire a cache up to e.g. Prometheus.
+type MetricsSink interface {
+	Inc(name string, labels ...string)
+}
+
+// WithMetricsSink mirrors the cache's built-in counters to sink as they're
+// incremented.
+func WithMetricsSink(sink MetricsSink) Option {
+	return func(c *config) { c.sink = sink }
+}
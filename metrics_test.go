@@ -0,0 +1,38 @@
+package lru
+
+import "testing"
+
+type testSink struct {
+	counts map[string]int
+}
+
+func (s *testSink) Inc(name string, labels ...string) {
+	if s.counts == nil {
+		s.counts = make(map[string]int)
+	}
+	s.counts[name]++
+}
+
+func TestStats(t *testing.T) {
+	sink := &testSink{}
+	c := New(nil, nil, 2, 0, WithMetricsSink(sink))
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Get("a")       // hit
+	c.Get("missing") // miss
+	c.Set("c", 3)    // evicts "b"
+
+	stats := c.Stats()
+	if stats.Hits != 1 {
+		t.Errorf("Hits = %d, want 1", stats.Hits)
+	}
+	if stats.Misses != 1 {
+		t.Errorf("Misses = %d, want 1", stats.Misses)
+	}
+	if stats.Evictions != 1 {
+		t.Errorf("Evictions = %d, want 1", stats.Evictions)
+	}
+	if sink.counts["hit"] != 1 || sink.counts["miss"] != 1 || sink.counts["eviction"] != 1 {
+		t.Errorf("sink counts = %#v", sink.counts)
+	}
+}
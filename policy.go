@@ -0,0 +1,155 @@
+// Pluggable eviction policies for LRU and Typed. A Policy only ever sees
+// *list.Element, so the same implementation works regardless of the cache's
+// key/value types.
+
+package lru
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Policy decides which element a cache evicts, and how access/insertion
+// affects that decision. A Policy value passed to WithPolicy is bound to the
+// single cache it configures: if it keeps per-cache state (e.g. SIEVE's
+// hand, which is a *list.Element owned by one cache's list.List), it must
+// implement clonablePolicy so each New/NewTyped call gets its own copy
+// instead of silently corrupting state shared with another cache.
+type Policy interface {
+	// OnAccess is called when an existing entry is looked up or updated via
+	// Get/Set, after the entry's TTL bookkeeping has been applied.
+	OnAccess(l *list.List, e *list.Element)
+	// OnInsert is called after a new entry has been pushed to the front of
+	// the list, before capacity is enforced.
+	OnInsert(l *list.List, e *list.Element)
+	// OnRemove is called whenever e leaves l through any path other than the
+	// element Evict itself just returned (Delete, InvalidateKeys,
+	// InvalidatePrefix, Flush, FlushN, FlushExpired, or the TTL-expiry branch
+	// of Get), so a policy tracking a pointer into the list (e.g. SIEVE's
+	// hand) can notice and drop it before it's used again.
+	OnRemove(l *list.List, e *list.Element)
+	// Evict returns the element to remove next, or nil if l is empty.
+	Evict(l *list.List) *list.Element
+}
+
+// policyEntry is implemented by *entry[K, V] so that policies can track
+// per-entry state without depending on the cache's key/value types.
+type policyEntry interface {
+	visited() bool
+	setVisited(bool)
+}
+
+// clonablePolicy is implemented by policies that hold cache-specific state
+// and so cannot be safely reused across multiple caches as-is. NewTyped
+// calls clone to get a fresh, independent copy for the cache it's
+// constructing, even if the same Policy value was passed to WithPolicy for
+// more than one cache.
+type clonablePolicy interface {
+	clone() Policy
+}
+
+// config holds the knobs configurable via Option, independent of the
+// cache's key/value types.
+type config struct {
+	policy             Policy
+	sink               MetricsSink
+	janitorInterval    time.Duration
+	invalidationSource <-chan Key
+}
+
+func parseConfig(opts []Option) *config {
+	cfg := &config{policy: LRUPolicy()}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// Option configures a Typed (or LRU) cache at construction time.
+type Option func(*config)
+
+// WithPolicy selects the eviction Policy used by a cache. The default, used
+// when no Option is given, is LRUPolicy.
+func WithPolicy(p Policy) Option {
+	return func(c *config) { c.policy = p }
+}
+
+// lruPolicy is the classic least-recently-used policy: Get/Set move the
+// entry to the front, and eviction always takes the back of the list.
+type lruPolicy struct{}
+
+// LRUPolicy is the default eviction Policy: least-recently used.
+func LRUPolicy() Policy { return lruPolicy{} }
+
+func (lruPolicy) OnAccess(l *list.List, e *list.Element) { l.MoveToFront(e) }
+func (lruPolicy) OnInsert(l *list.List, e *list.Element) {}
+func (lruPolicy) OnRemove(l *list.List, e *list.Element) {}
+func (lruPolicy) Evict(l *list.List) *list.Element       { return l.Back() }
+
+// sievePolicy implements SIEVE: a single FIFO queue plus a per-entry
+// "visited" bit and a moving "hand". Get sets visited without reordering
+// the queue; eviction walks the hand from tail toward head, clearing
+// visited bits it finds set, and evicts the first entry it finds with
+// visited=false (wrapping back to the tail if it reaches the head). This
+// gives near-ARC hit ratios with O(1) operations and no ghost lists.
+type sievePolicy struct {
+	mu   sync.Mutex
+	hand *list.Element
+}
+
+// SievePolicy returns a new SIEVE eviction Policy.
+func SievePolicy() Policy { return &sievePolicy{} }
+
+// clone returns a fresh sievePolicy with its own hand, so passing the same
+// SievePolicy() value to WithPolicy for two caches doesn't leave them
+// fighting over a *list.Element owned by only one of their lists.
+func (p *sievePolicy) clone() Policy { return &sievePolicy{} }
+
+func (p *sievePolicy) OnAccess(l *list.List, e *list.Element) {
+	e.Value.(policyEntry).setVisited(true)
+}
+
+func (p *sievePolicy) OnInsert(l *list.List, e *list.Element) {}
+
+// OnRemove drops the hand if e is it: otherwise the next Evict would find
+// e.Prev() nil (container/list clears an element's links on removal) and
+// mistake the detached element for one legitimately at the head, returning
+// it as an eviction target even though it already left the list.
+func (p *sievePolicy) OnRemove(l *list.List, e *list.Element) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.hand == e {
+		p.hand = nil
+	}
+}
+
+func (p *sievePolicy) Evict(l *list.List) *list.Element {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	hand := p.hand
+	if hand == nil {
+		hand = l.Back()
+	}
+	for hand != nil {
+		pe := hand.Value.(policyEntry)
+		if !pe.visited() {
+			prev := hand.Prev()
+			if prev == nil {
+				prev = l.Back()
+			}
+			p.hand = prev
+			return hand
+		}
+		pe.setVisited(false)
+		prev := hand.Prev()
+		if prev == nil {
+			prev = l.Back()
+		}
+		hand = prev
+	}
+	p.hand = nil
+	return nil
+}
@@ -0,0 +1,213 @@
+// ResourceCache is a close-on-evict LRU for io.Closer values such as open
+// file descriptors or net.Conns, where eviction must never race with a
+// reader still holding the value.
+
+package lru
+
+import (
+	"container/list"
+	"errors"
+	"io"
+	"sync"
+)
+
+// ErrCacheFull is returned by ResourceCache.Set when the cache is at
+// capacity and every entry is pinned, so nothing can be evicted to make
+// room for the new one.
+var ErrCacheFull = errors.New("lru: cache full, all entries pinned")
+
+// ErrKeyPinned is returned by ResourceCache.Set when key already has a
+// pinned (in-use) value: replacing it would close a value a concurrent
+// Handle still holds, so the caller's new value is closed and the existing
+// one is left untouched.
+var ErrKeyPinned = errors.New("lru: key is pinned, cannot replace its value")
+
+type resourceEntry[K comparable, V io.Closer] struct {
+	key      K
+	value    V
+	refcount int
+}
+
+// Handle pins a ResourceCache entry in place until Release is called,
+// preventing it from being evicted (and closed) while still in use. A
+// Handle must be released once the caller is done with it; calls to
+// Release after the first have no effect.
+type Handle[K comparable, V io.Closer] struct {
+	rc    *ResourceCache[K, V]
+	entry *list.Element
+	value V
+	once  sync.Once
+}
+
+// Value returns the pinned resource.
+func (h *Handle[K, V]) Value() V {
+	return h.value
+}
+
+// Release unpins the entry, making it eligible for eviction again. It is
+// safe to call more than once.
+func (h *Handle[K, V]) Release() {
+	h.once.Do(func() {
+		h.rc.release(h.entry)
+	})
+}
+
+// ResourceCache is an LRU cache for io.Closer values. RemovalFunc is
+// implicit: an evicted or deleted value has Close called on it. Get returns
+// a Handle that pins its entry (refcount++) until Release is called
+// (refcount--); pinned entries are skipped by eviction and re-queued, so a
+// concurrent reader can never have its value closed out from under it.
+type ResourceCache[K comparable, V io.Closer] struct {
+	mu       sync.Mutex
+	list     *list.List
+	table    map[K]*list.Element
+	capacity int
+}
+
+// NewResourceCache creates a new ResourceCache with the desired capacity.
+func NewResourceCache[K comparable, V io.Closer](capacity int) *ResourceCache[K, V] {
+	if capacity < 1 {
+		panic("capacity < 1")
+	}
+	return &ResourceCache[K, V]{
+		list:     list.New(),
+		table:    make(map[K]*list.Element),
+		capacity: capacity,
+	}
+}
+
+// Get fetches and pins the value for key, if present. The caller must call
+// Release on the returned Handle once done with the value.
+func (rc *ResourceCache[K, V]) Get(key K) (*Handle[K, V], bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	element := rc.table[key]
+	if element == nil {
+		return nil, false
+	}
+	e := element.Value.(*resourceEntry[K, V])
+	e.refcount++
+	rc.list.MoveToFront(element)
+	return &Handle[K, V]{rc: rc, entry: element, value: e.value}, true
+}
+
+// Set inserts value for key, pinning it for the caller (as Get would) and
+// returning a Handle. If key is already present and unpinned, its old value
+// is closed and replaced with value. If key is already present and pinned
+// (a concurrent Handle still holds the old value), replacing it would close
+// a value out from under that holder, so Set instead closes the caller's
+// value and returns ErrKeyPinned, leaving the cached entry untouched. If the
+// cache is at capacity and every existing entry is pinned, Set returns
+// ErrCacheFull instead of blocking.
+func (rc *ResourceCache[K, V]) Set(key K, value V) (*Handle[K, V], error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if element := rc.table[key]; element != nil {
+		e := element.Value.(*resourceEntry[K, V])
+		if e.refcount > 0 {
+			value.Close()
+			return nil, ErrKeyPinned
+		}
+		e.value.Close()
+		e.value = value
+		e.refcount = 1
+		rc.list.MoveToFront(element)
+		return &Handle[K, V]{rc: rc, entry: element, value: value}, nil
+	}
+
+	if rc.list.Len() >= rc.capacity {
+		if !rc.evictOne() {
+			return nil, ErrCacheFull
+		}
+	}
+
+	e := &resourceEntry[K, V]{key: key, value: value, refcount: 1}
+	element := rc.list.PushFront(e)
+	rc.table[key] = element
+	return &Handle[K, V]{rc: rc, entry: element, value: value}, nil
+}
+
+// Delete removes key, closing its value, if it is present and unpinned. It
+// reports whether the key was removed; a pinned entry is left untouched.
+func (rc *ResourceCache[K, V]) Delete(key K) bool {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	element := rc.table[key]
+	if element == nil {
+		return false
+	}
+	e := element.Value.(*resourceEntry[K, V])
+	if e.refcount > 0 {
+		return false
+	}
+	rc.list.Remove(element)
+	delete(rc.table, key)
+	e.value.Close()
+	return true
+}
+
+// Len returns the number of items currently in the cache, pinned or not.
+func (rc *ResourceCache[K, V]) Len() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return rc.list.Len()
+}
+
+func (rc *ResourceCache[K, V]) Capacity() int {
+	return rc.capacity
+}
+
+// Flush closes and removes every unpinned entry; pinned entries are left in
+// place.
+func (rc *ResourceCache[K, V]) Flush() {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	for e := rc.list.Front(); e != nil; {
+		next := e.Next()
+		entry := e.Value.(*resourceEntry[K, V])
+		if entry.refcount == 0 {
+			rc.list.Remove(e)
+			delete(rc.table, entry.key)
+			entry.value.Close()
+		}
+		e = next
+	}
+}
+
+// release is called by Handle.Release to unpin an entry.
+func (rc *ResourceCache[K, V]) release(element *list.Element) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	e := element.Value.(*resourceEntry[K, V])
+	if e.refcount > 0 {
+		e.refcount--
+	}
+}
+
+// evictOne closes and removes the least-recently-used unpinned entry,
+// skipping (and re-queuing to the front) any pinned entries it walks past.
+// It reports whether an entry was evicted.
+func (rc *ResourceCache[K, V]) evictOne() bool {
+	// Bounded to one pass over the list: if every entry is still pinned
+	// after that, re-queuing them would just cycle forever.
+	e := rc.list.Back()
+	for i := rc.list.Len(); i > 0 && e != nil; i-- {
+		prev := e.Prev()
+		entry := e.Value.(*resourceEntry[K, V])
+		if entry.refcount > 0 {
+			rc.list.MoveToFront(e)
+			e = prev
+			continue
+		}
+		rc.list.Remove(e)
+		delete(rc.table, entry.key)
+		entry.value.Close()
+		return true
+	}
+	return false
+}
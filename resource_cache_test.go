@@ -0,0 +1,152 @@
+package lru
+
+import (
+	"sync"
+	"testing"
+)
+
+type fakeConn struct {
+	closed bool
+}
+
+func (c *fakeConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestResourceCachePinPreventsEviction(t *testing.T) {
+	c := NewResourceCache[string, *fakeConn](2)
+
+	h1, err := c.Set("a", &fakeConn{})
+	if err != nil {
+		t.Fatalf("Set(a): %v", err)
+	}
+	hb, err := c.Set("b", &fakeConn{})
+	if err != nil {
+		t.Fatalf("Set(b): %v", err)
+	}
+	hb.Release()
+
+	// "a" is still pinned by h1: inserting "c" must evict "b", not "a",
+	// and must not close a's underlying value.
+	if _, err := c.Set("c", &fakeConn{}); err != nil {
+		t.Fatalf("Set(c): %v", err)
+	}
+	if h1.Value().closed {
+		t.Fatalf("pinned entry a was closed while still held")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Errorf("a should still be cached (pinned)")
+	}
+	if _, ok := c.Get("b"); ok {
+		t.Errorf("b should have been evicted instead of pinned a")
+	}
+
+	h1.Release()
+	h1.Release() // Release must be idempotent
+}
+
+func TestResourceCacheAllPinnedReturnsErrCacheFull(t *testing.T) {
+	c := NewResourceCache[string, *fakeConn](2)
+
+	ha, err := c.Set("a", &fakeConn{})
+	if err != nil {
+		t.Fatalf("Set(a): %v", err)
+	}
+	hb, err := c.Set("b", &fakeConn{})
+	if err != nil {
+		t.Fatalf("Set(b): %v", err)
+	}
+
+	if _, err := c.Set("c", &fakeConn{}); err != ErrCacheFull {
+		t.Fatalf("Set(c) = %v, want ErrCacheFull", err)
+	}
+
+	hb.Release()
+	if _, err := c.Set("c", &fakeConn{}); err != nil {
+		t.Fatalf("Set(c) after releasing b: %v", err)
+	}
+	ha.Release()
+}
+
+func TestResourceCacheSetReplacesUnpinnedValue(t *testing.T) {
+	c := NewResourceCache[string, *fakeConn](2)
+
+	connA := &fakeConn{}
+	ha, err := c.Set("a", connA)
+	if err != nil {
+		t.Fatalf("Set(a, connA): %v", err)
+	}
+	ha.Release()
+
+	connB := &fakeConn{}
+	hb, err := c.Set("a", connB)
+	if err != nil {
+		t.Fatalf("Set(a, connB): %v", err)
+	}
+	defer hb.Release()
+
+	if !connA.closed {
+		t.Errorf("replaced value connA should have been closed")
+	}
+	if hb.Value() != connB {
+		t.Errorf("Set(a, connB) handle = %v, want connB", hb.Value())
+	}
+	if c.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", c.Len())
+	}
+}
+
+func TestResourceCacheSetOnPinnedKeyReturnsErrKeyPinned(t *testing.T) {
+	c := NewResourceCache[string, *fakeConn](2)
+
+	connA := &fakeConn{}
+	ha, err := c.Set("a", connA)
+	if err != nil {
+		t.Fatalf("Set(a, connA): %v", err)
+	}
+	defer ha.Release()
+
+	connB := &fakeConn{}
+	if _, err := c.Set("a", connB); err != ErrKeyPinned {
+		t.Fatalf("Set(a, connB) = %v, want ErrKeyPinned", err)
+	}
+	if !connB.closed {
+		t.Errorf("rejected connB should have been closed, not leaked")
+	}
+	if ha.Value().closed {
+		t.Errorf("pinned connA should not have been closed")
+	}
+	if ha.Value() != connA {
+		t.Errorf("existing handle should still wrap connA")
+	}
+}
+
+func TestResourceCachePinReleaseRace(t *testing.T) {
+	c := NewResourceCache[string, *fakeConn](4)
+	for _, key := range []string{"a", "b", "c", "d"} {
+		h, err := c.Set(key, &fakeConn{})
+		if err != nil {
+			t.Fatalf("Set(%s): %v", key, err)
+		}
+		h.Release()
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := []string{"a", "b", "c", "d"}[i%4]
+			h, ok := c.Get(key)
+			if !ok {
+				return
+			}
+			if h.Value().closed {
+				t.Errorf("%s was closed while pinned", key)
+			}
+			h.Release()
+		}(i)
+	}
+	wg.Wait()
+}
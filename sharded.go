@@ -0,0 +1,248 @@
+// Sharded LRU cache: fans out to N independent LRU instances keyed by a hash
+// of the key, so that concurrent access no longer serializes on a single
+// mutex.
+
+package lru
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// Hasher maps a Key to a shard index. The same key must always hash to the
+// same value.
+type Hasher func(Key) uint64
+
+type shardedConfig struct {
+	hasher Hasher
+}
+
+// ShardedOption configures a ShardedLRU or ShardedLRUCounter at construction time.
+type ShardedOption func(*shardedConfig)
+
+// WithHasher selects the Hasher used to pick a shard for a key. The default
+// hashes strings and []byte directly, int as itself, and falls back to
+// fnv-1a over fmt.Sprint(key) for arbitrary key types.
+func WithHasher(h Hasher) ShardedOption {
+	return func(c *shardedConfig) { c.hasher = h }
+}
+
+func defaultHasher(key Key) uint64 {
+	switch k := key.(type) {
+	case string:
+		return fnv64a(k)
+	case []byte:
+		return fnv64aBytes(k)
+	case int:
+		return uint64(k)
+	case int64:
+		return uint64(k)
+	default:
+		return fnv64a(fmt.Sprint(key))
+	}
+}
+
+func fnv64a(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+func fnv64aBytes(b []byte) uint64 {
+	h := fnv.New64a()
+	h.Write(b)
+	return h.Sum64()
+}
+
+// ShardedLRU fans out to N independent LRU instances keyed by a hash of the
+// key, each with its own mutex, so that a hot workload spread across many
+// keys no longer serializes on a single lock. Capacity is split evenly
+// (rounded up) across shards.
+type ShardedLRU struct {
+	shards []*LRU
+	hasher Hasher
+}
+
+// NewSharded creates a ShardedLRU with shardCount shards, each an LRU built
+// with the given AddFunc, RemovalFunc, per-shard capacity share of capacity,
+// and ttl.
+func NewSharded(shardCount int, a AddFunc, r RemovalFunc, capacity int, ttl time.Duration, opts ...ShardedOption) *ShardedLRU {
+	if shardCount < 1 {
+		panic("shardCount < 1")
+	}
+	cfg := &shardedConfig{hasher: defaultHasher}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	perShard := (capacity + shardCount - 1) / shardCount
+	shards := make([]*LRU, shardCount)
+	for i := range shards {
+		shards[i] = New(a, r, perShard, ttl)
+	}
+	return &ShardedLRU{shards: shards, hasher: cfg.hasher}
+}
+
+func (s *ShardedLRU) shardFor(key Key) *LRU {
+	return s.shards[s.hasher(key)%uint64(len(s.shards))]
+}
+
+// Get fetches the value for key, delegating to the owning shard.
+func (s *ShardedLRU) Get(key Key) (Value, bool) {
+	return s.shardFor(key).Get(key)
+}
+
+// Set a new entry, delegating to the owning shard.
+func (s *ShardedLRU) Set(key Key, value Value) {
+	s.shardFor(key).Set(key, value)
+}
+
+// Delete removes key from its owning shard.
+func (s *ShardedLRU) Delete(key Key) bool {
+	return s.shardFor(key).Delete(key)
+}
+
+// Len returns the number of items currently cached, summed across all shards.
+func (s *ShardedLRU) Len() int {
+	total := 0
+	for _, shard := range s.shards {
+		total += shard.Len()
+	}
+	return total
+}
+
+// Capacity returns the total capacity summed across all shards.
+func (s *ShardedLRU) Capacity() int {
+	total := 0
+	for _, shard := range s.shards {
+		total += shard.Capacity()
+	}
+	return total
+}
+
+// Flush clears every shard, calling RemovalFunc as needed.
+func (s *ShardedLRU) Flush() {
+	for _, shard := range s.shards {
+		shard.Flush()
+	}
+}
+
+// FlushExpired flushes expired entries in every shard.
+func (s *ShardedLRU) FlushExpired() {
+	for _, shard := range s.shards {
+		shard.FlushExpired()
+	}
+}
+
+// Iter merges the per-shard iteration order. The overall order across shards
+// is not defined.
+func (s *ShardedLRU) Iter(keys chan Key, values chan Value) {
+	var wg sync.WaitGroup
+	for _, shard := range s.shards {
+		wg.Add(1)
+		go func(shard *LRU) {
+			defer wg.Done()
+			shardKeys := make(chan Key)
+			shardValues := make(chan Value)
+			go shard.Iter(shardKeys, shardValues)
+			for k := range shardKeys {
+				keys <- k
+				values <- <-shardValues
+			}
+		}(shard)
+	}
+	wg.Wait()
+	close(keys)
+	close(values)
+}
+
+// ShardedLRUCounter fans out to N independent LRUCounter instances keyed by
+// a hash of the key, so that a hot Incr workload stops serializing on a
+// single mutex.
+type ShardedLRUCounter struct {
+	shards []*LRUCounter
+	hasher Hasher
+}
+
+// NewShardedLRUCounterTTL creates a ShardedLRUCounter with shardCount shards,
+// each an LRUCounter built for removalFunc with a per-shard capacity share of
+// capacity and ttl.
+func NewShardedLRUCounterTTL(shardCount int, removalFunc func(interface{}, int64), capacity int, ttl time.Duration, opts ...ShardedOption) *ShardedLRUCounter {
+	if shardCount < 1 {
+		panic("shardCount < 1")
+	}
+	cfg := &shardedConfig{hasher: defaultHasher}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	perShard := (capacity + shardCount - 1) / shardCount
+	shards := make([]*LRUCounter, shardCount)
+	for i := range shards {
+		shards[i] = NewLRUCounterTTL(removalFunc, perShard, ttl)
+	}
+	return &ShardedLRUCounter{shards: shards, hasher: cfg.hasher}
+}
+
+// NewShardedLRUCounter creates a ShardedLRUCounter with shardCount shards,
+// each an LRUCounter built for removalFunc with a per-shard capacity share of
+// capacity.
+func NewShardedLRUCounter(shardCount int, removalFunc func(interface{}, int64), capacity int, opts ...ShardedOption) *ShardedLRUCounter {
+	return NewShardedLRUCounterTTL(shardCount, removalFunc, capacity, 0, opts...)
+}
+
+func (c *ShardedLRUCounter) shardFor(key interface{}) *LRUCounter {
+	return c.shards[c.hasher(key)%uint64(len(c.shards))]
+}
+
+// DisableTouchOnUpdate changes weather the timestamp used to compare TTL is updated when an element is updated
+func (c *ShardedLRUCounter) DisableTouchOnUpdate() {
+	for _, shard := range c.shards {
+		shard.DisableTouchOnUpdate()
+	}
+}
+
+// Fetch value for key in the cache, updating it's LRU position
+func (c *ShardedLRUCounter) Get(key interface{}) (int64, bool) {
+	return c.shardFor(key).Get(key)
+}
+
+// Len returns the number of items currently cached, summed across all shards.
+func (c *ShardedLRUCounter) Len() int {
+	total := 0
+	for _, shard := range c.shards {
+		total += shard.Len()
+	}
+	return total
+}
+
+// Capacity returns the total capacity summed across all shards.
+func (c *ShardedLRUCounter) Capacity() int {
+	total := 0
+	for _, shard := range c.shards {
+		total += shard.Capacity()
+	}
+	return total
+}
+
+// Flush clears every shard.
+func (c *ShardedLRUCounter) Flush() {
+	for _, shard := range c.shards {
+		shard.Flush()
+	}
+}
+
+// FlushExpired flushes expired entries in every shard.
+func (c *ShardedLRUCounter) FlushExpired() {
+	for _, shard := range c.shards {
+		shard.FlushExpired()
+	}
+}
+
+// Incr the key by value (goroutine safe), only contending with other Incr
+// calls that land on the same shard.
+func (c *ShardedLRUCounter) Incr(key interface{}, value int64) {
+	c.shardFor(key).Incr(key, value)
+}
@@ -0,0 +1,56 @@
+package lru
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestShardedLRU(t *testing.T) {
+	var removed int64
+	removal := func(k Key, v Value) {
+		removed++
+	}
+	s := NewSharded(4, nil, removal, 16, 0)
+	for i := 0; i < 100; i++ {
+		s.Set(fmt.Sprintf("key%d", i), i)
+	}
+	if s.Len() != s.Capacity() {
+		t.Errorf("Len() = %d, want Capacity() = %d", s.Len(), s.Capacity())
+	}
+	if s.Capacity() < 16 {
+		t.Errorf("Capacity() = %d, want at least 16", s.Capacity())
+	}
+	if removed == 0 {
+		t.Errorf("expected evictions across shards")
+	}
+
+	count := 0
+	keys := make(chan Key)
+	values := make(chan Value)
+	go s.Iter(keys, values)
+	for range keys {
+		<-values
+		count++
+	}
+	if count != s.Len() {
+		t.Errorf("Iter produced %d entries, want %d", count, s.Len())
+	}
+}
+
+func TestShardedLRUCounter(t *testing.T) {
+	var total int64
+	removal := func(k interface{}, v int64) {
+		total += v
+	}
+	c := NewShardedLRUCounter(4, removal, 16)
+	for i := 0; i < 50; i++ {
+		c.Incr(fmt.Sprintf("key%d", i%10), 1)
+	}
+	if v, ok := c.Get("key0"); !ok || v != 5 {
+		t.Errorf("Get(key0) = %d, %v; want 5, true", v, ok)
+	}
+	c.Flush()
+	if total != 50 {
+		t.Errorf("total = %d, want 50", total)
+	}
+}